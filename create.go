@@ -29,18 +29,43 @@ type attribute struct {
 	Type string
 }
 
-type tableOptions struct {
-	attributes         []attribute
+// globalSecondaryIndex and localSecondaryIndex are SDK-neutral descriptions
+// of an index, produced by WithGlobalSecondaryIndex / WithLocalSecondaryIndex.
+// Keeping them free of aws-sdk-go types lets both the v1 and v2 CreateTable
+// builders convert from the same representation.
+type globalSecondaryIndex struct {
+	indexName          string
 	keys               keyOptions
-	billingMode        string
-	globalIndexes      []func(billingMode string) (*dynamodb.GlobalSecondaryIndex, []attribute)
-	localIndexes       []func(billingMode string) (*dynamodb.LocalSecondaryIndex, []attribute)
+	nonKeyAttributes   []string
 	projectionType     string
+	billingMode        string
 	readCapacityUnits  int64
-	streamViewType     string
 	writeCapacityUnits int64
 }
 
+type localSecondaryIndex struct {
+	indexName        string
+	keys             keyOptions
+	nonKeyAttributes []string
+	projectionType   string
+}
+
+type tableOptions struct {
+	attributes          []attribute
+	keys                keyOptions
+	billingMode         string
+	globalIndexes       []func(billingMode string) (globalSecondaryIndex, []attribute)
+	localIndexes        []func(billingMode string) (localSecondaryIndex, []attribute)
+	projectionType      string
+	readCapacityUnits   int64
+	streamViewType      string
+	writeCapacityUnits  int64
+	ttlAttributeName    string
+	pointInTimeRecovery *bool
+	kmsKeyID            string
+	tags                map[string]string
+}
+
 type TableOption interface {
 	ApplyTable(o *tableOptions)
 }
@@ -81,18 +106,18 @@ func WithBillingMode(mode string) TableOption {
 
 func WithGlobalSecondaryIndex(indexName, projectionType string, opts ...IndexOption) TableOption {
 	return tableIndexFunc(func(o *tableOptions) {
-		o.globalIndexes = append(o.globalIndexes, func(billingMode string) (*dynamodb.GlobalSecondaryIndex, []attribute) {
+		o.globalIndexes = append(o.globalIndexes, func(billingMode string) (globalSecondaryIndex, []attribute) {
 			options := makeTableOptions(opts)
 			options.billingMode = billingMode
 
-			return &dynamodb.GlobalSecondaryIndex{
-				IndexName: aws.String(indexName),
-				KeySchema: makeKeySchemaElements(options),
-				Projection: &dynamodb.Projection{
-					NonKeyAttributes: aws.StringSlice(makeAttributeNames(options.attributes)),
-					ProjectionType:   aws.String(projectionType),
-				},
-				ProvisionedThroughput: makeProvisionedThroughput(options),
+			return globalSecondaryIndex{
+				indexName:          indexName,
+				keys:               options.keys,
+				nonKeyAttributes:   makeAttributeNames(options.attributes),
+				projectionType:     projectionType,
+				billingMode:        billingMode,
+				readCapacityUnits:  options.readCapacityUnits,
+				writeCapacityUnits: options.writeCapacityUnits,
 			}, options.attributes
 		})
 	})
@@ -109,17 +134,15 @@ func WithHashKey(attributeName, attributeType string) TableIndexOption {
 
 func WithLocalSecondaryIndex(indexName, projectionType string, opts ...IndexOption) TableOption {
 	return tableIndexFunc(func(o *tableOptions) {
-		o.localIndexes = append(o.localIndexes, func(billingMode string) (*dynamodb.LocalSecondaryIndex, []attribute) {
+		o.localIndexes = append(o.localIndexes, func(billingMode string) (localSecondaryIndex, []attribute) {
 			options := makeTableOptions(opts)
 			options.billingMode = billingMode
 
-			return &dynamodb.LocalSecondaryIndex{
-				IndexName: aws.String(indexName),
-				KeySchema: makeKeySchemaElements(options),
-				Projection: &dynamodb.Projection{
-					NonKeyAttributes: aws.StringSlice(makeAttributeNames(options.attributes)),
-					ProjectionType:   aws.String(projectionType),
-				},
+			return localSecondaryIndex{
+				indexName:        indexName,
+				keys:             options.keys,
+				nonKeyAttributes: makeAttributeNames(options.attributes),
+				projectionType:   projectionType,
 			}, options.attributes
 		})
 	})
@@ -177,31 +200,54 @@ func makeAttributeDefinitions(options tableOptions) []*dynamodb.AttributeDefinit
 	return items
 }
 
-func makeKeySchemaElements(options tableOptions) []*dynamodb.KeySchemaElement {
+func makeKeySchemaElements(keys keyOptions) []*dynamodb.KeySchemaElement {
 	var items []*dynamodb.KeySchemaElement
-	if options.keys.hashKey != nil {
+	if keys.hashKey != nil {
 		items = append(items, &dynamodb.KeySchemaElement{
-			AttributeName: aws.String(options.keys.hashKey.attributeName),
+			AttributeName: aws.String(keys.hashKey.attributeName),
 			KeyType:       aws.String(dynamodb.KeyTypeHash),
 		})
 	}
-	if options.keys.rangeKey != nil {
+	if keys.rangeKey != nil {
 		items = append(items, &dynamodb.KeySchemaElement{
-			AttributeName: aws.String(options.keys.rangeKey.attributeName),
+			AttributeName: aws.String(keys.rangeKey.attributeName),
 			KeyType:       aws.String(dynamodb.KeyTypeRange),
 		})
 	}
 	return items
 }
 
-func makeProvisionedThroughput(options tableOptions) *dynamodb.ProvisionedThroughput {
-	if options.billingMode == dynamodb.BillingModePayPerRequest {
+func makeProvisionedThroughput(billingMode string, rcap, wcap int64) *dynamodb.ProvisionedThroughput {
+	if billingMode == dynamodb.BillingModePayPerRequest {
 		return nil
 	}
 
 	return &dynamodb.ProvisionedThroughput{
-		ReadCapacityUnits:  aws.Int64(options.readCapacityUnits),
-		WriteCapacityUnits: aws.Int64(options.writeCapacityUnits),
+		ReadCapacityUnits:  aws.Int64(rcap),
+		WriteCapacityUnits: aws.Int64(wcap),
+	}
+}
+
+func makeGlobalSecondaryIndexV1(gsi globalSecondaryIndex) *dynamodb.GlobalSecondaryIndex {
+	return &dynamodb.GlobalSecondaryIndex{
+		IndexName: aws.String(gsi.indexName),
+		KeySchema: makeKeySchemaElements(gsi.keys),
+		Projection: &dynamodb.Projection{
+			NonKeyAttributes: aws.StringSlice(gsi.nonKeyAttributes),
+			ProjectionType:   aws.String(gsi.projectionType),
+		},
+		ProvisionedThroughput: makeProvisionedThroughput(gsi.billingMode, gsi.readCapacityUnits, gsi.writeCapacityUnits),
+	}
+}
+
+func makeLocalSecondaryIndexV1(lsi localSecondaryIndex) *dynamodb.LocalSecondaryIndex {
+	return &dynamodb.LocalSecondaryIndex{
+		IndexName: aws.String(lsi.indexName),
+		KeySchema: makeKeySchemaElements(lsi.keys),
+		Projection: &dynamodb.Projection{
+			NonKeyAttributes: aws.StringSlice(lsi.nonKeyAttributes),
+			ProjectionType:   aws.String(lsi.projectionType),
+		},
 	}
 }
 
@@ -227,13 +273,15 @@ func makeTableOptions(opts interface{}) tableOptions {
 }
 
 func makeCreateTableInput(tableName string, opts ...TableOption) dynamodb.CreateTableInput {
-	options := makeTableOptions(opts)
+	return buildCreateTableInput(tableName, makeTableOptions(opts))
+}
 
+func buildCreateTableInput(tableName string, options tableOptions) dynamodb.CreateTableInput {
 	input := dynamodb.CreateTableInput{
 		AttributeDefinitions:  makeAttributeDefinitions(options),
 		BillingMode:           aws.String(options.billingMode),
-		KeySchema:             makeKeySchemaElements(options),
-		ProvisionedThroughput: makeProvisionedThroughput(options),
+		KeySchema:             makeKeySchemaElements(options.keys),
+		ProvisionedThroughput: makeProvisionedThroughput(options.billingMode, options.readCapacityUnits, options.writeCapacityUnits),
 		TableName:             aws.String(tableName),
 	}
 	if options.streamViewType != "" {
@@ -242,14 +290,21 @@ func makeCreateTableInput(tableName string, opts ...TableOption) dynamodb.Create
 			StreamViewType: aws.String(options.streamViewType),
 		}
 	}
+	if options.kmsKeyID != "" {
+		input.SSESpecification = &dynamodb.SSESpecification{
+			Enabled:        aws.Bool(true),
+			SSEType:        aws.String(dynamodb.SSETypeKms),
+			KMSMasterKeyId: aws.String(options.kmsKeyID),
+		}
+	}
 	for _, fn := range options.globalIndexes {
 		gsi, attributes := fn(options.billingMode)
-		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, makeGlobalSecondaryIndexV1(gsi))
 		input.AttributeDefinitions = merge(input.AttributeDefinitions, attributes...)
 	}
 	for _, fn := range options.localIndexes {
 		lsi, attributes := fn(options.billingMode)
-		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, lsi)
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, makeLocalSecondaryIndexV1(lsi))
 		input.AttributeDefinitions = merge(input.AttributeDefinitions, attributes...)
 	}
 
@@ -261,7 +316,8 @@ func (t *Table) CreateTableIfNotExists(ctx context.Context, hashKeyName, hashKey
 	mergedOpts = append(mergedOpts, WithHashKey(hashKeyName, hashKeyType))
 	mergedOpts = append(mergedOpts, opts...)
 
-	input := makeCreateTableInput(t.tableName, mergedOpts...)
+	options := makeTableOptions(mergedOpts)
+	input := buildCreateTableInput(t.tableName, options)
 	if _, err := t.api.CreateTableWithContext(ctx, &input); err != nil {
 		if v, ok := err.(awserr.Error); ok && v.Code() == dynamodb.ErrCodeResourceInUseException {
 			return nil
@@ -269,7 +325,15 @@ func (t *Table) CreateTableIfNotExists(ctx context.Context, hashKeyName, hashKey
 		return err
 	}
 
-	return nil
+	// TTL, PITR, and tags are applied after creation, so wait for the table
+	// to leave CREATING before issuing those calls.
+	if err := t.api.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	}); err != nil {
+		return err
+	}
+
+	return t.applyPostCreateOptions(ctx, options)
 }
 
 func merge(definitions []*dynamodb.AttributeDefinition, attributes ...attribute) []*dynamodb.AttributeDefinition {
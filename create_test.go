@@ -205,6 +205,22 @@ func (m *Mock) DeleteTableWithContext(aws.Context, *dynamodb.DeleteTableInput, .
 	return &dynamodb.DeleteTableOutput{}, m.err
 }
 
+func (m *Mock) WaitUntilTableExistsWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.WaiterOption) error {
+	return nil
+}
+
+func (m *Mock) UpdateTimeToLiveWithContext(aws.Context, *dynamodb.UpdateTimeToLiveInput, ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func (m *Mock) UpdateContinuousBackupsWithContext(aws.Context, *dynamodb.UpdateContinuousBackupsInput, ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return &dynamodb.UpdateContinuousBackupsOutput{}, nil
+}
+
+func (m *Mock) TagResourceWithContext(aws.Context, *dynamodb.TagResourceInput, ...request.Option) (*dynamodb.TagResourceOutput, error) {
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
 func TestCreateTable(t *testing.T) {
 	var (
 		ctx         = context.Background()
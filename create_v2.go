@@ -0,0 +1,157 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func makeAttributeDefinitionsV2(options tableOptions) []types.AttributeDefinition {
+	var items []types.AttributeDefinition
+	if options.keys.hashKey != nil {
+		items = append(items, types.AttributeDefinition{
+			AttributeName: &options.keys.hashKey.attributeName,
+			AttributeType: types.ScalarAttributeType(options.keys.hashKey.attributeType),
+		})
+	}
+	if options.keys.rangeKey != nil {
+		items = append(items, types.AttributeDefinition{
+			AttributeName: &options.keys.rangeKey.attributeName,
+			AttributeType: types.ScalarAttributeType(options.keys.rangeKey.attributeType),
+		})
+	}
+	return items
+}
+
+func makeKeySchemaElementsV2(keys keyOptions) []types.KeySchemaElement {
+	var items []types.KeySchemaElement
+	if keys.hashKey != nil {
+		items = append(items, types.KeySchemaElement{
+			AttributeName: &keys.hashKey.attributeName,
+			KeyType:       types.KeyTypeHash,
+		})
+	}
+	if keys.rangeKey != nil {
+		items = append(items, types.KeySchemaElement{
+			AttributeName: &keys.rangeKey.attributeName,
+			KeyType:       types.KeyTypeRange,
+		})
+	}
+	return items
+}
+
+func makeProvisionedThroughputV2(billingMode string, rcap, wcap int64) *types.ProvisionedThroughput {
+	if billingMode == string(types.BillingModePayPerRequest) {
+		return nil
+	}
+
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  &rcap,
+		WriteCapacityUnits: &wcap,
+	}
+}
+
+func makeGlobalSecondaryIndexV2(gsi globalSecondaryIndex) types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName: &gsi.indexName,
+		KeySchema: makeKeySchemaElementsV2(gsi.keys),
+		Projection: &types.Projection{
+			NonKeyAttributes: gsi.nonKeyAttributes,
+			ProjectionType:   types.ProjectionType(gsi.projectionType),
+		},
+		ProvisionedThroughput: makeProvisionedThroughputV2(gsi.billingMode, gsi.readCapacityUnits, gsi.writeCapacityUnits),
+	}
+}
+
+func makeLocalSecondaryIndexV2(lsi localSecondaryIndex) types.LocalSecondaryIndex {
+	return types.LocalSecondaryIndex{
+		IndexName: &lsi.indexName,
+		KeySchema: makeKeySchemaElementsV2(lsi.keys),
+		Projection: &types.Projection{
+			NonKeyAttributes: lsi.nonKeyAttributes,
+			ProjectionType:   types.ProjectionType(lsi.projectionType),
+		},
+	}
+}
+
+func makeCreateTableInputV2(tableName string, opts ...TableOption) dynamodb.CreateTableInput {
+	options := makeTableOptions(opts)
+
+	input := dynamodb.CreateTableInput{
+		AttributeDefinitions:  makeAttributeDefinitionsV2(options),
+		BillingMode:           types.BillingMode(options.billingMode),
+		KeySchema:             makeKeySchemaElementsV2(options.keys),
+		ProvisionedThroughput: makeProvisionedThroughputV2(options.billingMode, options.readCapacityUnits, options.writeCapacityUnits),
+		TableName:             &tableName,
+	}
+	if options.streamViewType != "" {
+		input.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: types.StreamViewType(options.streamViewType),
+		}
+	}
+	if options.kmsKeyID != "" {
+		input.SSESpecification = &types.SSESpecification{
+			Enabled:        aws.Bool(true),
+			SSEType:        types.SSETypeKms,
+			KMSMasterKeyId: aws.String(options.kmsKeyID),
+		}
+	}
+	for _, fn := range options.globalIndexes {
+		gsi, attributes := fn(options.billingMode)
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, makeGlobalSecondaryIndexV2(gsi))
+		input.AttributeDefinitions = mergeV2(input.AttributeDefinitions, attributes...)
+	}
+	for _, fn := range options.localIndexes {
+		lsi, attributes := fn(options.billingMode)
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, makeLocalSecondaryIndexV2(lsi))
+		input.AttributeDefinitions = mergeV2(input.AttributeDefinitions, attributes...)
+	}
+
+	return input
+}
+
+func mergeV2(definitions []types.AttributeDefinition, attributes ...attribute) []types.AttributeDefinition {
+	var (
+		seen   = map[string]struct{}{}
+		merged []types.AttributeDefinition
+	)
+
+	for _, item := range definitions {
+		seen[*item.AttributeName] = struct{}{}
+		merged = append(merged, item)
+	}
+
+	for _, attr := range attributes {
+		if _, ok := seen[attr.Name]; ok {
+			continue
+		}
+		seen[attr.Name] = struct{}{}
+		merged = append(merged, types.AttributeDefinition{
+			AttributeName: aws.String(attr.Name),
+			AttributeType: types.ScalarAttributeType(attr.Type),
+		})
+	}
+
+	return merged
+}
+
+func (t *TableV2) CreateTableIfNotExists(ctx context.Context, hashKeyName, hashKeyType string, opts ...TableOption) error {
+	var mergedOpts []TableOption
+	mergedOpts = append(mergedOpts, WithHashKey(hashKeyName, hashKeyType))
+	mergedOpts = append(mergedOpts, opts...)
+
+	input := makeCreateTableInputV2(t.tableName, mergedOpts...)
+	if _, err := t.api.CreateTable(ctx, &input); err != nil {
+		var inUse *types.ResourceInUseException
+		if errors.As(err, &inUse) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
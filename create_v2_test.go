@@ -0,0 +1,97 @@
+package dynamo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func Test_makeCreateTableInputV2(t *testing.T) {
+	const (
+		tableName = "blah"
+		hashKey   = "hash"
+		rangeKey  = "range"
+	)
+
+	t.Run("minimal", func(t *testing.T) {
+		got := makeCreateTableInputV2(tableName,
+			WithHashKey(hashKey, string(types.ScalarAttributeTypeS)),
+			WithRangeKey(rangeKey, string(types.ScalarAttributeTypeN)),
+		)
+		want := dynamodb.CreateTableInput{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{
+					AttributeName: aws.String(hashKey),
+					AttributeType: types.ScalarAttributeTypeS,
+				},
+				{
+					AttributeName: aws.String(rangeKey),
+					AttributeType: types.ScalarAttributeTypeN,
+				},
+			},
+			BillingMode: types.BillingMode(DefaultBillingMode),
+			KeySchema: []types.KeySchemaElement{
+				{
+					AttributeName: aws.String(hashKey),
+					KeyType:       types.KeyTypeHash,
+				},
+				{
+					AttributeName: aws.String(rangeKey),
+					KeyType:       types.KeyTypeRange,
+				},
+			},
+			ProvisionedThroughput: &types.ProvisionedThroughput{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+			TableName: aws.String(tableName),
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	})
+
+	t.Run("pay per request", func(t *testing.T) {
+		got := makeCreateTableInputV2(tableName,
+			WithBillingMode(string(types.BillingModePayPerRequest)),
+		)
+		want := dynamodb.CreateTableInput{
+			BillingMode: types.BillingModePayPerRequest,
+			TableName:   aws.String(tableName),
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %#v; want %#v", got, want)
+		}
+	})
+
+	t.Run("global secondary index with multiple new attributes", func(t *testing.T) {
+		got := makeCreateTableInputV2(tableName,
+			WithHashKey(hashKey, string(types.ScalarAttributeTypeS)),
+			WithGlobalSecondaryIndex("gsi", string(types.ProjectionTypeAll),
+				WithAttr("gsiHash", string(types.ScalarAttributeTypeS)),
+				WithAttr("gsiRange", string(types.ScalarAttributeTypeN)),
+			),
+		)
+
+		names := map[string]types.ScalarAttributeType{}
+		for _, def := range got.AttributeDefinitions {
+			names[aws.ToString(def.AttributeName)] = def.AttributeType
+		}
+		want := map[string]types.ScalarAttributeType{
+			hashKey:    types.ScalarAttributeTypeS,
+			"gsiHash":  types.ScalarAttributeTypeS,
+			"gsiRange": types.ScalarAttributeTypeN,
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Fatalf("got %#v; want %#v", names, want)
+		}
+		for _, def := range got.AttributeDefinitions {
+			if def.AttributeName == nil || *def.AttributeName == "" {
+				t.Fatalf("got %#v; want every AttributeDefinition to have its own name", got.AttributeDefinitions)
+			}
+		}
+	})
+}
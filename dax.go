@@ -0,0 +1,40 @@
+package dynamo
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// DAXAPI is the narrow item-operation surface Table routes through DAX when
+// built with NewWithDAX. It is satisfied by both github.com/aws/aws-dax-go's
+// client and a github.com/aws/aws-dax-go-v2 client wrapped to this v1
+// signature, so either can be plugged in without this package depending on
+// a specific DAX SDK. dynamodbiface.DynamoDBAPI also satisfies it, which is
+// what lets Table fall back to the plain DynamoDB client when no DAX client
+// is configured.
+type DAXAPI interface {
+	GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error)
+	PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error)
+	UpdateItemWithContext(aws.Context, *dynamodb.UpdateItemInput, ...request.Option) (*dynamodb.UpdateItemOutput, error)
+	DeleteItemWithContext(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error)
+	QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error)
+	ScanWithContext(aws.Context, *dynamodb.ScanInput, ...request.Option) (*dynamodb.ScanOutput, error)
+	BatchGetItemWithContext(aws.Context, *dynamodb.BatchGetItemInput, ...request.Option) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItemWithContext(aws.Context, *dynamodb.BatchWriteItemInput, ...request.Option) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// NewWithDAX builds a Table that routes item-level operations (Get, Put,
+// Update, Delete, Query, Scan, and the batch variants) through dax.
+// Control-plane operations (CreateTable, DeleteTable, DescribeTable,
+// UpdateTable, and the TTL/PITR/tags follow-up calls) always use ddb
+// directly, since DAX does not implement them.
+func NewWithDAX(dax DAXAPI, ddb dynamodbiface.DynamoDBAPI, tableName string, opts ...TableOption) *Table {
+	return &Table{
+		api:       ddb,
+		itemAPI:   dax,
+		tableName: tableName,
+		schema:    makeTableOptions(opts),
+	}
+}
@@ -0,0 +1,82 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// fakeDAX implements DAXAPI and fails any call, so tests can assert that
+// control-plane operations never reach it.
+type fakeDAX struct{}
+
+func (fakeDAX) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) UpdateItemWithContext(aws.Context, *dynamodb.UpdateItemInput, ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) DeleteItemWithContext(aws.Context, *dynamodb.DeleteItemInput, ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) QueryWithContext(aws.Context, *dynamodb.QueryInput, ...request.Option) (*dynamodb.QueryOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) ScanWithContext(aws.Context, *dynamodb.ScanInput, ...request.Option) (*dynamodb.ScanOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) BatchGetItemWithContext(aws.Context, *dynamodb.BatchGetItemInput, ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func (fakeDAX) BatchWriteItemWithContext(aws.Context, *dynamodb.BatchWriteItemInput, ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	return nil, errors.New("unexpected call to DAX")
+}
+
+func TestNewWithDAX(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		tableName = "blah"
+		ddb       = &Mock{}
+		dax       = fakeDAX{}
+	)
+
+	table := NewWithDAX(dax, ddb, tableName)
+
+	t.Run("control plane calls use the DynamoDB client, never DAX", func(t *testing.T) {
+		if err := table.CreateTableIfNotExists(ctx, "id", dynamodb.ScalarAttributeTypeS); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+		if err := table.DeleteTableIfExists(ctx); err != nil {
+			t.Fatalf("got %v; want nil", err)
+		}
+	})
+
+	t.Run("item operations route through DAX", func(t *testing.T) {
+		if table.itemClient() != dax {
+			t.Fatalf("got %#v; want the configured DAX client", table.itemClient())
+		}
+	})
+}
+
+func TestNew_itemClientFallsBackToDynamoDB(t *testing.T) {
+	ddb := &Mock{}
+	table := New(ddb, "blah")
+
+	if table.itemClient() != ddb {
+		t.Fatalf("got %#v; want the DynamoDB client", table.itemClient())
+	}
+}
@@ -0,0 +1,324 @@
+// Package dynamotest provides an in-memory, dynamodbiface.DynamoDBAPI-
+// compatible fake for unit testing code built on github.com/savaki/dynamo,
+// without spinning up DynamoDB Local or hand-writing every method a test
+// happens to touch.
+package dynamotest
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// StreamEvent is emitted to subscribers registered via OnStream whenever an
+// item is written to, or removed from, a table created with a stream
+// specification (dynamo.WithStreamSpecification).
+type StreamEvent struct {
+	TableName string
+	EventName string // INSERT, MODIFY, or REMOVE
+	NewImage  map[string]*dynamodb.AttributeValue
+	OldImage  map[string]*dynamodb.AttributeValue
+}
+
+type table struct {
+	desc       *dynamodb.TableDescription
+	streamType string
+	items      map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (tb *table) keyNames() (hash, rng string) {
+	for _, ks := range tb.desc.KeySchema {
+		switch aws.StringValue(ks.KeyType) {
+		case dynamodb.KeyTypeHash:
+			hash = aws.StringValue(ks.AttributeName)
+		case dynamodb.KeyTypeRange:
+			rng = aws.StringValue(ks.AttributeName)
+		}
+	}
+	return hash, rng
+}
+
+func (tb *table) keyFor(item map[string]*dynamodb.AttributeValue) (string, bool) {
+	hashName, rangeName := tb.keyNames()
+
+	hv, ok := item[hashName]
+	if !ok {
+		return "", false
+	}
+
+	key := avString(hv)
+	if rangeName != "" {
+		rv, ok := item[rangeName]
+		if !ok {
+			return "", false
+		}
+		key += "|" + avString(rv)
+	}
+
+	return key, true
+}
+
+func avString(av *dynamodb.AttributeValue) string {
+	switch {
+	case av.S != nil:
+		return *av.S
+	case av.N != nil:
+		return *av.N
+	case av.B != nil:
+		return string(av.B)
+	default:
+		return ""
+	}
+}
+
+// Client is an in-memory fake of the subset of dynamodbiface.DynamoDBAPI
+// that github.com/savaki/dynamo's Table depends on. It honors the schema
+// declared via dynamo's TableOption builders: hash/range keys, global and
+// local secondary indexes with their projections, and stream view type.
+//
+// Embedding dynamodbiface.DynamoDBAPI means any method Client does not
+// implement panics if called, the same trade-off the package's own test
+// Mock makes, rather than silently behaving like a real client would.
+type Client struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu     sync.Mutex
+	tables map[string]*table
+
+	streamSubscribers []chan StreamEvent
+}
+
+// New returns an empty in-memory Client.
+func New() *Client {
+	return &Client{tables: map[string]*table{}}
+}
+
+// OnStream registers ch to receive a StreamEvent for every item written to,
+// or removed from, a table created with a stream specification. Delivery is
+// best-effort and non-blocking: if ch is unbuffered or full, the event is
+// dropped rather than blocking the call that triggered it. The caller owns
+// ch and is responsible for keeping it drained, or sufficiently buffered,
+// to avoid missing events.
+func (c *Client) OnStream(ch chan StreamEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streamSubscribers = append(c.streamSubscribers, ch)
+}
+
+// publish is called from putItem/deleteItem while c.mu is held, so sends
+// must not block: a subscriber with an unbuffered or full channel would
+// otherwise deadlock the fake.
+func (c *Client) publish(event StreamEvent) {
+	for _, ch := range c.streamSubscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (c *Client) CreateTableWithContext(_ aws.Context, input *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := aws.StringValue(input.TableName)
+	if _, ok := c.tables[name]; ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceInUseException, "dynamotest: table already exists", nil)
+	}
+
+	desc := &dynamodb.TableDescription{
+		TableName:              input.TableName,
+		TableStatus:            aws.String(dynamodb.TableStatusActive),
+		TableArn:               aws.String("arn:aws:dynamodb:dynamotest:000000000000:table/" + name),
+		KeySchema:              input.KeySchema,
+		AttributeDefinitions:   input.AttributeDefinitions,
+		GlobalSecondaryIndexes: makeGSIDescriptions(input.GlobalSecondaryIndexes),
+		LocalSecondaryIndexes:  makeLSIDescriptions(input.LocalSecondaryIndexes),
+	}
+	if input.ProvisionedThroughput != nil {
+		desc.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  input.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: input.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	if input.BillingMode != nil {
+		desc.BillingModeSummary = &dynamodb.BillingModeSummary{BillingMode: input.BillingMode}
+	}
+
+	streamType := ""
+	if input.StreamSpecification != nil && aws.BoolValue(input.StreamSpecification.StreamEnabled) {
+		streamType = aws.StringValue(input.StreamSpecification.StreamViewType)
+		desc.StreamSpecification = input.StreamSpecification
+	}
+
+	c.tables[name] = &table{
+		desc:       desc,
+		streamType: streamType,
+		items:      map[string]map[string]*dynamodb.AttributeValue{},
+	}
+
+	return &dynamodb.CreateTableOutput{TableDescription: desc}, nil
+}
+
+func (c *Client) DeleteTableWithContext(_ aws.Context, input *dynamodb.DeleteTableInput, _ ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := aws.StringValue(input.TableName)
+	tb, ok := c.tables[name]
+	if !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "dynamotest: table not found", nil)
+	}
+	delete(c.tables, name)
+
+	return &dynamodb.DeleteTableOutput{TableDescription: tb.desc}, nil
+}
+
+func (c *Client) DescribeTableWithContext(_ aws.Context, input *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.DescribeTableOutput{Table: tb.desc}, nil
+}
+
+func (c *Client) UpdateTableWithContext(_ aws.Context, input *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	if input.BillingMode != nil {
+		tb.desc.BillingModeSummary = &dynamodb.BillingModeSummary{BillingMode: input.BillingMode}
+	}
+	if input.ProvisionedThroughput != nil {
+		tb.desc.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  input.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: input.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	tb.desc.AttributeDefinitions = mergeAttributeDefinitions(tb.desc.AttributeDefinitions, input.AttributeDefinitions)
+
+	for _, update := range input.GlobalSecondaryIndexUpdates {
+		switch {
+		case update.Create != nil:
+			created := &dynamodb.GlobalSecondaryIndexDescription{
+				IndexName:  update.Create.IndexName,
+				KeySchema:  update.Create.KeySchema,
+				Projection: update.Create.Projection,
+			}
+			if update.Create.ProvisionedThroughput != nil {
+				created.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+					ReadCapacityUnits:  update.Create.ProvisionedThroughput.ReadCapacityUnits,
+					WriteCapacityUnits: update.Create.ProvisionedThroughput.WriteCapacityUnits,
+				}
+			}
+			tb.desc.GlobalSecondaryIndexes = append(tb.desc.GlobalSecondaryIndexes, created)
+		case update.Delete != nil:
+			name := aws.StringValue(update.Delete.IndexName)
+			var kept []*dynamodb.GlobalSecondaryIndexDescription
+			for _, gsi := range tb.desc.GlobalSecondaryIndexes {
+				if aws.StringValue(gsi.IndexName) != name {
+					kept = append(kept, gsi)
+				}
+			}
+			tb.desc.GlobalSecondaryIndexes = kept
+		case update.Update != nil:
+			if update.Update.ProvisionedThroughput == nil {
+				continue
+			}
+			for _, gsi := range tb.desc.GlobalSecondaryIndexes {
+				if aws.StringValue(gsi.IndexName) == aws.StringValue(update.Update.IndexName) {
+					gsi.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  update.Update.ProvisionedThroughput.ReadCapacityUnits,
+						WriteCapacityUnits: update.Update.ProvisionedThroughput.WriteCapacityUnits,
+					}
+				}
+			}
+		}
+	}
+
+	return &dynamodb.UpdateTableOutput{TableDescription: tb.desc}, nil
+}
+
+func (c *Client) WaitUntilTableExistsWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.WaiterOption) error {
+	return nil
+}
+
+func (c *Client) UpdateTimeToLiveWithContext(aws.Context, *dynamodb.UpdateTimeToLiveInput, ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func (c *Client) UpdateContinuousBackupsWithContext(aws.Context, *dynamodb.UpdateContinuousBackupsInput, ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return &dynamodb.UpdateContinuousBackupsOutput{}, nil
+}
+
+func (c *Client) TagResourceWithContext(aws.Context, *dynamodb.TagResourceInput, ...request.Option) (*dynamodb.TagResourceOutput, error) {
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
+func (c *Client) mustTable(name string) (*table, error) {
+	tb, ok := c.tables[name]
+	if !ok {
+		return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "dynamotest: table not found", nil)
+	}
+	return tb, nil
+}
+
+func makeGSIDescriptions(gsis []*dynamodb.GlobalSecondaryIndex) []*dynamodb.GlobalSecondaryIndexDescription {
+	var out []*dynamodb.GlobalSecondaryIndexDescription
+	for _, gsi := range gsis {
+		desc := &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:  gsi.IndexName,
+			KeySchema:  gsi.KeySchema,
+			Projection: gsi.Projection,
+		}
+		if gsi.ProvisionedThroughput != nil {
+			desc.ProvisionedThroughput = &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  gsi.ProvisionedThroughput.ReadCapacityUnits,
+				WriteCapacityUnits: gsi.ProvisionedThroughput.WriteCapacityUnits,
+			}
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+func makeLSIDescriptions(lsis []*dynamodb.LocalSecondaryIndex) []*dynamodb.LocalSecondaryIndexDescription {
+	var out []*dynamodb.LocalSecondaryIndexDescription
+	for _, lsi := range lsis {
+		out = append(out, &dynamodb.LocalSecondaryIndexDescription{
+			IndexName:  lsi.IndexName,
+			KeySchema:  lsi.KeySchema,
+			Projection: lsi.Projection,
+		})
+	}
+	return out
+}
+
+func mergeAttributeDefinitions(existing, additional []*dynamodb.AttributeDefinition) []*dynamodb.AttributeDefinition {
+	seen := make(map[string]struct{}, len(existing))
+	merged := existing
+	for _, a := range existing {
+		seen[aws.StringValue(a.AttributeName)] = struct{}{}
+	}
+	for _, a := range additional {
+		if _, ok := seen[aws.StringValue(a.AttributeName)]; ok {
+			continue
+		}
+		seen[aws.StringValue(a.AttributeName)] = struct{}{}
+		merged = append(merged, a)
+	}
+	return merged
+}
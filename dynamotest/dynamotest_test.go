@@ -0,0 +1,103 @@
+package dynamotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/savaki/dynamo"
+)
+
+type widget struct {
+	ID   string `dynamodbav:"id"`
+	Name string `dynamodbav:"name"`
+}
+
+func TestClient_createGetPut(t *testing.T) {
+	ctx := context.Background()
+	client := New()
+	table := dynamo.New(client, "widgets", dynamo.WithHashKey("id", dynamodb.ScalarAttributeTypeS))
+
+	if err := table.CreateTableIfNotExists(ctx, "id", dynamodb.ScalarAttributeTypeS); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if err := table.Put(ctx, widget{ID: "a", Name: "gadget"}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	var got widget
+	if err := table.Get(ctx, "a", nil, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != (widget{ID: "a", Name: "gadget"}) {
+		t.Fatalf("got %#v; want widget{a, gadget}", got)
+	}
+
+	if err := table.Delete(ctx, "a", nil); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if err := table.Get(ctx, "a", nil, &got); err != dynamo.ErrNotFound {
+		t.Fatalf("got %v; want ErrNotFound", err)
+	}
+}
+
+func TestClient_streamEvents(t *testing.T) {
+	ctx := context.Background()
+	client := New()
+	table := dynamo.New(client, "widgets", dynamo.WithHashKey("id", dynamodb.ScalarAttributeTypeS))
+
+	err := table.CreateTableIfNotExists(ctx, "id", dynamodb.ScalarAttributeTypeS,
+		dynamo.WithStreamSpecification(dynamodb.StreamViewTypeNewAndOldImages),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	events := make(chan StreamEvent, 2)
+	client.OnStream(events)
+
+	if err := table.Put(ctx, widget{ID: "a", Name: "gadget"}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.EventName != "INSERT" {
+			t.Fatalf("got %q; want INSERT", event.EventName)
+		}
+	default:
+		t.Fatal("got no stream event; want one")
+	}
+}
+
+func TestClient_streamEvents_fullSubscriberDoesNotBlock(t *testing.T) {
+	ctx := context.Background()
+	client := New()
+	table := dynamo.New(client, "widgets", dynamo.WithHashKey("id", dynamodb.ScalarAttributeTypeS))
+
+	err := table.CreateTableIfNotExists(ctx, "id", dynamodb.ScalarAttributeTypeS,
+		dynamo.WithStreamSpecification(dynamodb.StreamViewTypeNewAndOldImages),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	// An unbuffered channel with no reader would deadlock a blocking publish.
+	client.OnStream(make(chan StreamEvent))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := table.Put(ctx, widget{ID: "a", Name: "gadget"}); err != nil {
+			t.Errorf("got %v; want nil", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Put did not return; publish blocked on a full subscriber channel")
+	}
+}
@@ -0,0 +1,324 @@
+package dynamotest
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func (c *Client) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := tb.keyFor(input.Key)
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+
+	return &dynamodb.GetItemOutput{Item: tb.items[key]}, nil
+}
+
+func (c *Client) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	c.putItem(aws.StringValue(input.TableName), tb, input.Item)
+
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// putItem stores item and, if the table has a stream enabled, publishes the
+// resulting StreamEvent. Callers must hold c.mu.
+func (c *Client) putItem(tableName string, tb *table, item map[string]*dynamodb.AttributeValue) {
+	key, ok := tb.keyFor(item)
+	if !ok {
+		return
+	}
+
+	old, existed := tb.items[key]
+	tb.items[key] = item
+
+	if tb.streamType == "" {
+		return
+	}
+	eventName := "INSERT"
+	if existed {
+		eventName = "MODIFY"
+	}
+	c.publish(StreamEvent{TableName: tableName, EventName: eventName, NewImage: item, OldImage: old})
+}
+
+func (c *Client) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	c.deleteItem(aws.StringValue(input.TableName), tb, input.Key)
+
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (c *Client) deleteItem(tableName string, tb *table, key map[string]*dynamodb.AttributeValue) {
+	k, ok := tb.keyFor(key)
+	if !ok {
+		return
+	}
+
+	old, existed := tb.items[k]
+	if !existed {
+		return
+	}
+	delete(tb.items, k)
+
+	if tb.streamType != "" {
+		c.publish(StreamEvent{TableName: tableName, EventName: "REMOVE", OldImage: old})
+	}
+}
+
+// UpdateItemWithContext supports only the single-clause "SET #n = :n, ..."
+// expressions that dynamo.Table.Update generates; it is a test double, not
+// a DynamoDB expression engine. Unrecognized expressions are a no-op beyond
+// creating the item if it doesn't already exist.
+func (c *Client) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := tb.keyFor(input.Key)
+	if !ok {
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+
+	item := map[string]*dynamodb.AttributeValue{}
+	for k, v := range tb.items[key] {
+		item[k] = v
+	}
+	for k, v := range input.Key {
+		item[k] = v
+	}
+
+	for name, value := range parseSetExpression(aws.StringValue(input.UpdateExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues) {
+		item[name] = value
+	}
+
+	c.putItem(aws.StringValue(input.TableName), tb, item)
+
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// parseSetExpression extracts "name = value" assignments from a SET-only
+// UpdateExpression of the form produced by expression.UpdateBuilder.Set:
+// "SET #0 = :0, #1 = :1, ...".
+func parseSetExpression(expr string, names map[string]*string, values map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	out := map[string]*dynamodb.AttributeValue{}
+
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "SET ")
+	if expr == "" {
+		return out
+	}
+
+	for _, clause := range strings.Split(expr, ",") {
+		parts := strings.SplitN(clause, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		nameToken := strings.TrimSpace(parts[0])
+		valueToken := strings.TrimSpace(parts[1])
+
+		name := nameToken
+		if n, ok := names[nameToken]; ok {
+			name = aws.StringValue(n)
+		}
+		value, ok := values[valueToken]
+		if !ok {
+			continue
+		}
+		out[name] = value
+	}
+
+	return out
+}
+
+func (c *Client) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	match := equalityMatcher(aws.StringValue(input.KeyConditionExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+
+	var items []map[string]*dynamodb.AttributeValue
+	for _, item := range tb.items {
+		if match(item) {
+			items = append(items, item)
+		}
+	}
+
+	return &dynamodb.QueryOutput{Items: items, Count: aws.Int64(int64(len(items)))}, nil
+}
+
+func (c *Client) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tb, err := c.mustTable(aws.StringValue(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	match := equalityMatcher(aws.StringValue(input.FilterExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+
+	var items []map[string]*dynamodb.AttributeValue
+	for _, item := range tb.items {
+		if match(item) {
+			items = append(items, item)
+		}
+	}
+
+	return &dynamodb.ScanOutput{Items: items, Count: aws.Int64(int64(len(items)))}, nil
+}
+
+// equalityMatcher builds a predicate from an expression of the form
+// "#0 = :0 AND #1 = :1 ...", the shape dynamo.Table's Query generates for a
+// hash key alone and Scan generates for a single equality filter. A clause in
+// any other form, including the parenthesised "(#0 = :0) AND (...)" clauses
+// produced when conditions are combined, is ignored and narrows nothing. A
+// blank expression matches everything.
+func equalityMatcher(expr string, names map[string]*string, values map[string]*dynamodb.AttributeValue) func(map[string]*dynamodb.AttributeValue) bool {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return func(map[string]*dynamodb.AttributeValue) bool { return true }
+	}
+
+	type clause struct {
+		name  string
+		value *dynamodb.AttributeValue
+	}
+	var clauses []clause
+	for _, part := range strings.Split(expr, " AND ") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		nameToken := strings.TrimSpace(kv[0])
+		valueToken := strings.TrimSpace(kv[1])
+
+		name := nameToken
+		if n, ok := names[nameToken]; ok {
+			name = aws.StringValue(n)
+		}
+		value, ok := values[valueToken]
+		if !ok {
+			continue
+		}
+		clauses = append(clauses, clause{name: name, value: value})
+	}
+
+	return func(item map[string]*dynamodb.AttributeValue) bool {
+		for _, cl := range clauses {
+			av, ok := item[cl.name]
+			if !ok || avString(av) != avString(cl.value) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (c *Client) BatchGetItemWithContext(_ aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responses := map[string][]map[string]*dynamodb.AttributeValue{}
+	for tableName, keysAndAttrs := range input.RequestItems {
+		tb, err := c.mustTable(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keysAndAttrs.Keys {
+			k, ok := tb.keyFor(key)
+			if !ok {
+				continue
+			}
+			if item, ok := tb.items[k]; ok {
+				responses[tableName] = append(responses[tableName], item)
+			}
+		}
+	}
+
+	return &dynamodb.BatchGetItemOutput{Responses: responses}, nil
+}
+
+func (c *Client) BatchWriteItemWithContext(_ aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for tableName, reqs := range input.RequestItems {
+		tb, err := c.mustTable(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, req := range reqs {
+			switch {
+			case req.PutRequest != nil:
+				c.putItem(tableName, tb, req.PutRequest.Item)
+			case req.DeleteRequest != nil:
+				c.deleteItem(tableName, tb, req.DeleteRequest.Key)
+			}
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (c *Client) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, item := range input.TransactItems {
+		switch {
+		case item.Put != nil:
+			tableName := aws.StringValue(item.Put.TableName)
+			tb, err := c.mustTable(tableName)
+			if err != nil {
+				return nil, err
+			}
+			c.putItem(tableName, tb, item.Put.Item)
+		case item.Delete != nil:
+			tableName := aws.StringValue(item.Delete.TableName)
+			tb, err := c.mustTable(tableName)
+			if err != nil {
+				return nil, err
+			}
+			c.deleteItem(tableName, tb, item.Delete.Key)
+		}
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
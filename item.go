@@ -0,0 +1,407 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+const (
+	// maxBatchGetKeys is the BatchGetItem service limit on keys per request.
+	maxBatchGetKeys = 100
+	// maxBatchWriteItems is the BatchWriteItem service limit on requests per
+	// call.
+	maxBatchWriteItems = 25
+	// maxBatchRetries bounds how many times BatchGet/BatchWrite retry
+	// UnprocessedKeys/UnprocessedItems before giving up.
+	maxBatchRetries = 5
+)
+
+// ErrNotFound is returned by Get when no item matches the given key.
+var ErrNotFound = errors.New("dynamo: item not found")
+
+// Key identifies an item by its hash key and, for tables with one, its
+// range key. Range must be nil for hash-key-only tables; a non-nil Range on
+// such a table is an error.
+type Key struct {
+	Hash  interface{}
+	Range interface{}
+}
+
+func (t *Table) keyAV(hashKey, rangeKey interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	if t.schema.keys.hashKey == nil {
+		return nil, errors.New("dynamo: table has no hash key configured; pass WithHashKey to New")
+	}
+
+	hv, err := dynamodbattribute.Marshal(hashKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := map[string]*dynamodb.AttributeValue{
+		t.schema.keys.hashKey.attributeName: hv,
+	}
+
+	if rangeKey != nil {
+		if t.schema.keys.rangeKey == nil {
+			return nil, errors.New("dynamo: table has no range key configured; pass WithRangeKey to New")
+		}
+		rv, err := dynamodbattribute.Marshal(rangeKey)
+		if err != nil {
+			return nil, err
+		}
+		key[t.schema.keys.rangeKey.attributeName] = rv
+	}
+
+	return key, nil
+}
+
+// Get fetches the item identified by hashKey (and rangeKey, for tables that
+// have one) and unmarshals it into out. It returns ErrNotFound if no item
+// matches.
+func (t *Table) Get(ctx context.Context, hashKey, rangeKey interface{}, out interface{}) error {
+	key, err := t.keyAV(hashKey, rangeKey)
+	if err != nil {
+		return err
+	}
+
+	output, err := t.itemClient().GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.tableName),
+		Key:       key,
+	})
+	if err != nil {
+		return err
+	}
+	if output.Item == nil {
+		return ErrNotFound
+	}
+
+	return dynamodbattribute.UnmarshalMap(output.Item, out)
+}
+
+// Put marshals item with struct tags and writes it to the table.
+func (t *Table) Put(ctx context.Context, item interface{}) error {
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.itemClient().PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(t.tableName),
+		Item:      av,
+	})
+	return err
+}
+
+// Update sets the named attributes on the item identified by hashKey (and
+// rangeKey, for tables that have one).
+func (t *Table) Update(ctx context.Context, hashKey, rangeKey interface{}, sets map[string]interface{}) error {
+	key, err := t.keyAV(hashKey, rangeKey)
+	if err != nil {
+		return err
+	}
+
+	var update expression.UpdateBuilder
+	for name, value := range sets {
+		update = update.Set(expression.Name(name), expression.Value(value))
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		return err
+	}
+
+	_, err = t.itemClient().UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(t.tableName),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	return err
+}
+
+// Delete removes the item identified by hashKey (and rangeKey, for tables
+// that have one).
+func (t *Table) Delete(ctx context.Context, hashKey, rangeKey interface{}) error {
+	key, err := t.keyAV(hashKey, rangeKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.itemClient().DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(t.tableName),
+		Key:       key,
+	})
+	return err
+}
+
+// Query runs a query for the given hash key, optionally refined by range
+// key conditions (e.g. expression.Key("range").BeginsWith("foo")), and
+// unmarshals the results into out, which must be a pointer to a slice. Query
+// follows LastEvaluatedKey until DynamoDB reports the result set exhausted,
+// so out receives every matching item rather than just the first page.
+func (t *Table) Query(ctx context.Context, hashKey interface{}, out interface{}, rangeKeyConds ...expression.KeyConditionBuilder) error {
+	if t.schema.keys.hashKey == nil {
+		return errors.New("dynamo: table has no hash key configured; pass WithHashKey to New")
+	}
+
+	cond := expression.Key(t.schema.keys.hashKey.attributeName).Equal(expression.Value(hashKey))
+	for _, c := range rangeKeyConds {
+		cond = cond.And(c)
+	}
+
+	expr, err := expression.NewBuilder().WithKeyCondition(cond).Build()
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(t.tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	for {
+		output, err := t.itemClient().QueryWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		items = append(items, output.Items...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return dynamodbattribute.UnmarshalListOfMaps(items, out)
+}
+
+// Scan reads the whole table, optionally narrowed by filter conditions, and
+// unmarshals the results into out, which must be a pointer to a slice. Scan
+// follows LastEvaluatedKey until DynamoDB reports the result set exhausted,
+// so out receives every item in the table rather than just the first page.
+func (t *Table) Scan(ctx context.Context, out interface{}, filters ...expression.ConditionBuilder) error {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(t.tableName),
+	}
+
+	if len(filters) > 0 {
+		cond := filters[0]
+		for _, c := range filters[1:] {
+			cond = cond.And(c)
+		}
+
+		expr, err := expression.NewBuilder().WithFilter(cond).Build()
+		if err != nil {
+			return err
+		}
+		input.FilterExpression = expr.Filter()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	for {
+		output, err := t.itemClient().ScanWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		items = append(items, output.Items...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	return dynamodbattribute.UnmarshalListOfMaps(items, out)
+}
+
+// BatchGet fetches multiple items by key and unmarshals the results into
+// out, which must be a pointer to a slice. Keys are split into chunks of at
+// most maxBatchGetKeys, the BatchGetItem service limit, and any keys
+// DynamoDB reports as UnprocessedKeys (e.g. due to throttling) are retried
+// with exponential backoff before BatchGet gives up and returns an error.
+func (t *Table) BatchGet(ctx context.Context, keys []Key, out interface{}) error {
+	avs := make([]map[string]*dynamodb.AttributeValue, 0, len(keys))
+	for _, k := range keys {
+		av, err := t.keyAV(k.Hash, k.Range)
+		if err != nil {
+			return err
+		}
+		avs = append(avs, av)
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	for len(avs) > 0 {
+		n := maxBatchGetKeys
+		if n > len(avs) {
+			n = len(avs)
+		}
+		chunk := avs[:n]
+		avs = avs[n:]
+
+		got, err := t.batchGetChunk(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		items = append(items, got...)
+	}
+
+	return dynamodbattribute.UnmarshalListOfMaps(items, out)
+}
+
+// batchGetChunk fetches at most maxBatchGetKeys items, retrying any keys
+// DynamoDB reports as UnprocessedKeys with exponential backoff.
+func (t *Table) batchGetChunk(ctx context.Context, keys []map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	for attempt := 0; len(keys) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchRetries {
+				return nil, fmt.Errorf("dynamo: batch get gave up after %d retries with %d keys still unprocessed", maxBatchRetries, len(keys))
+			}
+			if err := batchBackoff(ctx, attempt); err != nil {
+				return nil, err
+			}
+		}
+
+		output, err := t.itemClient().BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]*dynamodb.KeysAndAttributes{
+				t.tableName: {Keys: keys},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, output.Responses[t.tableName]...)
+
+		keys = nil
+		if ka, ok := output.UnprocessedKeys[t.tableName]; ok {
+			keys = ka.Keys
+		}
+	}
+
+	return items, nil
+}
+
+// BatchWrite puts and deletes multiple items. Requests are split into
+// chunks of at most maxBatchWriteItems, the BatchWriteItem service limit,
+// and any requests DynamoDB reports as UnprocessedItems (e.g. due to
+// throttling) are retried with exponential backoff before BatchWrite gives
+// up and returns an error.
+func (t *Table) BatchWrite(ctx context.Context, puts []interface{}, deletes []Key) error {
+	reqs := make([]*dynamodb.WriteRequest, 0, len(puts)+len(deletes))
+	for _, item := range puts {
+		av, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return err
+		}
+		reqs = append(reqs, &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{Item: av},
+		})
+	}
+	for _, k := range deletes {
+		av, err := t.keyAV(k.Hash, k.Range)
+		if err != nil {
+			return err
+		}
+		reqs = append(reqs, &dynamodb.WriteRequest{
+			DeleteRequest: &dynamodb.DeleteRequest{Key: av},
+		})
+	}
+
+	for len(reqs) > 0 {
+		n := maxBatchWriteItems
+		if n > len(reqs) {
+			n = len(reqs)
+		}
+		chunk := reqs[:n]
+		reqs = reqs[n:]
+
+		if err := t.batchWriteChunk(ctx, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchWriteChunk writes at most maxBatchWriteItems requests, retrying any
+// DynamoDB reports as UnprocessedItems with exponential backoff.
+func (t *Table) batchWriteChunk(ctx context.Context, reqs []*dynamodb.WriteRequest) error {
+	for attempt := 0; len(reqs) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchRetries {
+				return fmt.Errorf("dynamo: batch write gave up after %d retries with %d requests still unprocessed", maxBatchRetries, len(reqs))
+			}
+			if err := batchBackoff(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		output, err := t.itemClient().BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{
+				t.tableName: reqs,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		reqs = output.UnprocessedItems[t.tableName]
+	}
+
+	return nil
+}
+
+// batchBackoff waits before the given retry attempt of a batch operation,
+// returning early if ctx is canceled.
+func batchBackoff(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Duration(1<<uint(attempt-1)) * 50 * time.Millisecond):
+		return nil
+	}
+}
+
+// TransactWrite puts and deletes multiple items atomically. DAX does not
+// support transactions, so this always goes through the regular DynamoDB
+// client rather than itemClient.
+func (t *Table) TransactWrite(ctx context.Context, puts []interface{}, deletes []Key) error {
+	items := make([]*dynamodb.TransactWriteItem, 0, len(puts)+len(deletes))
+	for _, item := range puts {
+		av, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{TableName: aws.String(t.tableName), Item: av},
+		})
+	}
+	for _, k := range deletes {
+		av, err := t.keyAV(k.Hash, k.Range)
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{TableName: aws.String(t.tableName), Key: av},
+		})
+	}
+
+	_, err := t.api.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	return err
+}
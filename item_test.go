@@ -0,0 +1,434 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type widget struct {
+	ID   string `dynamodbav:"id"`
+	Rank int    `dynamodbav:"rank"`
+	Name string `dynamodbav:"name"`
+}
+
+type itemMock struct {
+	Mock
+
+	getInput  *dynamodb.GetItemInput
+	getOutput *dynamodb.GetItemOutput
+
+	putInput *dynamodb.PutItemInput
+
+	updateInput *dynamodb.UpdateItemInput
+
+	deleteInput *dynamodb.DeleteItemInput
+
+	queryInputs  []*dynamodb.QueryInput
+	queryOutputs []*dynamodb.QueryOutput
+
+	scanOutputs []*dynamodb.ScanOutput
+	scanCalls   int
+
+	batchGetInputs  []*dynamodb.BatchGetItemInput
+	batchGetOutputs []*dynamodb.BatchGetItemOutput
+
+	batchWriteInputs  []*dynamodb.BatchWriteItemInput
+	batchWriteOutputs []*dynamodb.BatchWriteItemOutput
+
+	transactInput *dynamodb.TransactWriteItemsInput
+}
+
+func (m *itemMock) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	m.getInput = input
+	return m.getOutput, nil
+}
+
+func (m *itemMock) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	m.putInput = input
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (m *itemMock) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	m.updateInput = input
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (m *itemMock) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	m.deleteInput = input
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (m *itemMock) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	m.queryInputs = append(m.queryInputs, input)
+
+	i := len(m.queryInputs) - 1
+	if i >= len(m.queryOutputs) {
+		return &dynamodb.QueryOutput{}, nil
+	}
+	return m.queryOutputs[i], nil
+}
+
+func (m *itemMock) ScanWithContext(_ aws.Context, _ *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	i := m.scanCalls
+	m.scanCalls++
+	if i >= len(m.scanOutputs) {
+		return &dynamodb.ScanOutput{}, nil
+	}
+	return m.scanOutputs[i], nil
+}
+
+func (m *itemMock) BatchGetItemWithContext(_ aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	m.batchGetInputs = append(m.batchGetInputs, input)
+
+	i := len(m.batchGetInputs) - 1
+	if i >= len(m.batchGetOutputs) {
+		return &dynamodb.BatchGetItemOutput{}, nil
+	}
+	return m.batchGetOutputs[i], nil
+}
+
+func (m *itemMock) BatchWriteItemWithContext(_ aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	m.batchWriteInputs = append(m.batchWriteInputs, input)
+
+	i := len(m.batchWriteInputs) - 1
+	if i >= len(m.batchWriteOutputs) {
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}
+	return m.batchWriteOutputs[i], nil
+}
+
+func (m *itemMock) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	m.transactInput = input
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func newItemTable(mock *itemMock) *Table {
+	return New(mock, "widgets",
+		WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+		WithRangeKey("rank", dynamodb.ScalarAttributeTypeN),
+	)
+}
+
+func TestTable_Get(t *testing.T) {
+	av, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	mock := &itemMock{getOutput: &dynamodb.GetItemOutput{Item: av}}
+	table := newItemTable(mock)
+
+	var got widget
+	if err := table.Get(context.Background(), "a", 1, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if got != (widget{ID: "a", Rank: 1, Name: "gadget"}) {
+		t.Fatalf("got %#v; want widget{a, 1, gadget}", got)
+	}
+
+	key := mock.getInput.Key
+	if aws.StringValue(key["id"].S) != "a" || aws.StringValue(key["rank"].N) != "1" {
+		t.Fatalf("got key %#v; want hash/range key attribute values", key)
+	}
+}
+
+func TestTable_Get_notFound(t *testing.T) {
+	mock := &itemMock{getOutput: &dynamodb.GetItemOutput{}}
+	table := newItemTable(mock)
+
+	var got widget
+	err := table.Get(context.Background(), "a", 1, &got)
+	if err != ErrNotFound {
+		t.Fatalf("got %v; want ErrNotFound", err)
+	}
+}
+
+func TestTable_Put(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	if err := table.Put(context.Background(), widget{ID: "a", Rank: 1, Name: "gadget"}); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if aws.StringValue(mock.putInput.Item["name"].S) != "gadget" {
+		t.Fatalf("got %#v; want marshalled item", mock.putInput.Item)
+	}
+}
+
+func TestTable_Update(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	err := table.Update(context.Background(), "a", 1, map[string]interface{}{"name": "widget"})
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if mock.updateInput.UpdateExpression == nil {
+		t.Fatalf("got nil UpdateExpression")
+	}
+}
+
+func TestTable_Delete(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	if err := table.Delete(context.Background(), "a", 1); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if aws.StringValue(mock.deleteInput.Key["id"].S) != "a" {
+		t.Fatalf("got %#v; want key for id=a", mock.deleteInput.Key)
+	}
+}
+
+func TestTable_Query(t *testing.T) {
+	av, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	mock := &itemMock{queryOutputs: []*dynamodb.QueryOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{av}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.Query(context.Background(), "a", &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 1 || got[0].Name != "gadget" {
+		t.Fatalf("got %#v; want one gadget", got)
+	}
+	if mock.queryInputs[0].KeyConditionExpression == nil {
+		t.Fatalf("got nil KeyConditionExpression")
+	}
+}
+
+func TestTable_Query_followsLastEvaluatedKey(t *testing.T) {
+	av1, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	av2, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 2, Name: "gizmo"})
+	lastKey := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}, "rank": {N: aws.String("1")}}
+
+	mock := &itemMock{queryOutputs: []*dynamodb.QueryOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{av1}, LastEvaluatedKey: lastKey},
+		{Items: []map[string]*dynamodb.AttributeValue{av2}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.Query(context.Background(), "a", &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d items; want 2 across both pages", len(got))
+	}
+	if len(mock.queryInputs) != 2 {
+		t.Fatalf("got %d QueryWithContext calls; want 2", len(mock.queryInputs))
+	}
+	if mock.queryInputs[1].ExclusiveStartKey == nil {
+		t.Fatalf("got nil ExclusiveStartKey on second call; want LastEvaluatedKey carried forward")
+	}
+}
+
+func TestTable_Scan(t *testing.T) {
+	av, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	mock := &itemMock{scanOutputs: []*dynamodb.ScanOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{av}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.Scan(context.Background(), &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 1 || got[0].Name != "gadget" {
+		t.Fatalf("got %#v; want one gadget", got)
+	}
+}
+
+func TestTable_Scan_followsLastEvaluatedKey(t *testing.T) {
+	av1, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	av2, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 2, Name: "gizmo"})
+	lastKey := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}, "rank": {N: aws.String("1")}}
+
+	mock := &itemMock{scanOutputs: []*dynamodb.ScanOutput{
+		{Items: []map[string]*dynamodb.AttributeValue{av1}, LastEvaluatedKey: lastKey},
+		{Items: []map[string]*dynamodb.AttributeValue{av2}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.Scan(context.Background(), &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d items; want 2 across both pages", len(got))
+	}
+	if mock.scanCalls != 2 {
+		t.Fatalf("got %d ScanWithContext calls; want 2", mock.scanCalls)
+	}
+}
+
+func TestTable_BatchWrite(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	err := table.BatchWrite(context.Background(),
+		[]interface{}{widget{ID: "a", Rank: 1, Name: "gadget"}},
+		[]Key{{Hash: "b", Range: 2}},
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(mock.batchWriteInputs) != 1 || len(mock.batchWriteInputs[0].RequestItems["widgets"]) != 2 {
+		t.Fatalf("got %#v; want a single call with 2 requests", mock.batchWriteInputs)
+	}
+}
+
+func TestTable_BatchWrite_retriesUnprocessedItems(t *testing.T) {
+	req := &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{}}}
+	mock := &itemMock{batchWriteOutputs: []*dynamodb.BatchWriteItemOutput{
+		{UnprocessedItems: map[string][]*dynamodb.WriteRequest{"widgets": {req}}},
+		{},
+	}}
+	table := newItemTable(mock)
+
+	err := table.BatchWrite(context.Background(), []interface{}{widget{ID: "a", Rank: 1, Name: "gadget"}}, nil)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(mock.batchWriteInputs) != 2 {
+		t.Fatalf("got %d BatchWriteItemWithContext calls; want 2 (initial + retry)", len(mock.batchWriteInputs))
+	}
+}
+
+func TestTable_BatchWrite_givesUpAfterMaxRetries(t *testing.T) {
+	req := &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{}}}
+	var outputs []*dynamodb.BatchWriteItemOutput
+	for i := 0; i < maxBatchRetries+2; i++ {
+		outputs = append(outputs, &dynamodb.BatchWriteItemOutput{
+			UnprocessedItems: map[string][]*dynamodb.WriteRequest{"widgets": {req}},
+		})
+	}
+	mock := &itemMock{batchWriteOutputs: outputs}
+	table := newItemTable(mock)
+
+	err := table.BatchWrite(context.Background(), []interface{}{widget{ID: "a", Rank: 1, Name: "gadget"}}, nil)
+	if err == nil {
+		t.Fatal("got nil; want an error after exhausting retries")
+	}
+}
+
+func TestTable_BatchWrite_chunksToServiceLimit(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	puts := make([]interface{}, maxBatchWriteItems+1)
+	for i := range puts {
+		puts[i] = widget{ID: "a", Rank: i, Name: "gadget"}
+	}
+
+	if err := table.BatchWrite(context.Background(), puts, nil); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(mock.batchWriteInputs) != 2 {
+		t.Fatalf("got %d calls; want 2 (%d then 1 requests)", len(mock.batchWriteInputs), maxBatchWriteItems)
+	}
+	if len(mock.batchWriteInputs[0].RequestItems["widgets"]) != maxBatchWriteItems {
+		t.Fatalf("got %d requests in first call; want %d", len(mock.batchWriteInputs[0].RequestItems["widgets"]), maxBatchWriteItems)
+	}
+	if len(mock.batchWriteInputs[1].RequestItems["widgets"]) != 1 {
+		t.Fatalf("got %d requests in second call; want 1", len(mock.batchWriteInputs[1].RequestItems["widgets"]))
+	}
+}
+
+func TestTable_BatchGet(t *testing.T) {
+	av, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	mock := &itemMock{batchGetOutputs: []*dynamodb.BatchGetItemOutput{
+		{Responses: map[string][]map[string]*dynamodb.AttributeValue{"widgets": {av}}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.BatchGet(context.Background(), []Key{{Hash: "a", Range: 1}}, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 1 || got[0].Name != "gadget" {
+		t.Fatalf("got %#v; want one gadget", got)
+	}
+}
+
+func TestTable_BatchGet_retriesUnprocessedKeys(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}, "rank": {N: aws.String("1")}}
+	av, _ := dynamodbattribute.MarshalMap(widget{ID: "a", Rank: 1, Name: "gadget"})
+	mock := &itemMock{batchGetOutputs: []*dynamodb.BatchGetItemOutput{
+		{UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{"widgets": {Keys: []map[string]*dynamodb.AttributeValue{key}}}},
+		{Responses: map[string][]map[string]*dynamodb.AttributeValue{"widgets": {av}}},
+	}}
+	table := newItemTable(mock)
+
+	var got []widget
+	if err := table.BatchGet(context.Background(), []Key{{Hash: "a", Range: 1}}, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d items; want 1 after retry", len(got))
+	}
+	if len(mock.batchGetInputs) != 2 {
+		t.Fatalf("got %d BatchGetItemWithContext calls; want 2 (initial + retry)", len(mock.batchGetInputs))
+	}
+}
+
+func TestTable_BatchGet_givesUpAfterMaxRetries(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("a")}, "rank": {N: aws.String("1")}}
+	var outputs []*dynamodb.BatchGetItemOutput
+	for i := 0; i < maxBatchRetries+2; i++ {
+		outputs = append(outputs, &dynamodb.BatchGetItemOutput{
+			UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{"widgets": {Keys: []map[string]*dynamodb.AttributeValue{key}}},
+		})
+	}
+	mock := &itemMock{batchGetOutputs: outputs}
+	table := newItemTable(mock)
+
+	var got []widget
+	err := table.BatchGet(context.Background(), []Key{{Hash: "a", Range: 1}}, &got)
+	if err == nil {
+		t.Fatal("got nil; want an error after exhausting retries")
+	}
+}
+
+func TestTable_BatchGet_chunksToServiceLimit(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	keys := make([]Key, maxBatchGetKeys+1)
+	for i := range keys {
+		keys[i] = Key{Hash: "a", Range: i}
+	}
+
+	var got []widget
+	if err := table.BatchGet(context.Background(), keys, &got); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(mock.batchGetInputs) != 2 {
+		t.Fatalf("got %d calls; want 2 (%d then 1 keys)", len(mock.batchGetInputs), maxBatchGetKeys)
+	}
+	if len(mock.batchGetInputs[0].RequestItems["widgets"].Keys) != maxBatchGetKeys {
+		t.Fatalf("got %d keys in first call; want %d", len(mock.batchGetInputs[0].RequestItems["widgets"].Keys), maxBatchGetKeys)
+	}
+	if len(mock.batchGetInputs[1].RequestItems["widgets"].Keys) != 1 {
+		t.Fatalf("got %d keys in second call; want 1", len(mock.batchGetInputs[1].RequestItems["widgets"].Keys))
+	}
+}
+
+func TestTable_TransactWrite(t *testing.T) {
+	mock := &itemMock{}
+	table := newItemTable(mock)
+
+	err := table.TransactWrite(context.Background(),
+		[]interface{}{widget{ID: "a", Rank: 1, Name: "gadget"}},
+		[]Key{{Hash: "b", Range: 2}},
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+	if len(mock.transactInput.TransactItems) != 2 {
+		t.Fatalf("got %d items; want 2", len(mock.transactInput.TransactItems))
+	}
+}
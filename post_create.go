@@ -0,0 +1,93 @@
+package dynamo
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// WithTimeToLive enables TTL on the table, expiring items based on the unix
+// epoch value stored in attributeName. It is applied via UpdateTimeToLive
+// once the table is ACTIVE, since DynamoDB does not accept a TTL
+// specification in CreateTable.
+func WithTimeToLive(attributeName string) TableOption {
+	return tableIndexFunc(func(o *tableOptions) {
+		o.ttlAttributeName = attributeName
+	})
+}
+
+// WithPointInTimeRecovery enables or disables point-in-time recovery. It is
+// applied via UpdateContinuousBackups once the table is ACTIVE.
+func WithPointInTimeRecovery(enabled bool) TableOption {
+	return tableIndexFunc(func(o *tableOptions) {
+		o.pointInTimeRecovery = aws.Bool(enabled)
+	})
+}
+
+// WithServerSideEncryption enables SSE using the given KMS key. Unlike TTL
+// and PITR, this is part of CreateTableInput itself rather than a follow-up
+// call.
+func WithServerSideEncryption(kmsKeyID string) TableOption {
+	return tableIndexFunc(func(o *tableOptions) {
+		o.kmsKeyID = kmsKeyID
+	})
+}
+
+// WithTags attaches the given tags to the table. They are applied via
+// TagResource once the table is ACTIVE and its ARN is known.
+func WithTags(tags map[string]string) TableOption {
+	return tableIndexFunc(func(o *tableOptions) {
+		o.tags = tags
+	})
+}
+
+// applyPostCreateOptions issues the UpdateTimeToLive, UpdateContinuousBackups,
+// and TagResource calls for options that DynamoDB cannot set at CreateTable
+// time. Callers must wait for the table to become ACTIVE first.
+func (t *Table) applyPostCreateOptions(ctx context.Context, options tableOptions) error {
+	if options.ttlAttributeName != "" {
+		if _, err := t.api.UpdateTimeToLiveWithContext(ctx, &dynamodb.UpdateTimeToLiveInput{
+			TableName: aws.String(t.tableName),
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: aws.String(options.ttlAttributeName),
+				Enabled:       aws.Bool(true),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if options.pointInTimeRecovery != nil {
+		if _, err := t.api.UpdateContinuousBackupsWithContext(ctx, &dynamodb.UpdateContinuousBackupsInput{
+			TableName: aws.String(t.tableName),
+			PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+				PointInTimeRecoveryEnabled: options.pointInTimeRecovery,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(options.tags) > 0 {
+		out, err := t.api.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+			TableName: aws.String(t.tableName),
+		})
+		if err != nil {
+			return err
+		}
+
+		var tags []*dynamodb.Tag
+		for k, v := range options.tags {
+			tags = append(tags, &dynamodb.Tag{Key: aws.String(k), Value: aws.String(v)})
+		}
+		if _, err := t.api.TagResourceWithContext(ctx, &dynamodb.TagResourceInput{
+			ResourceArn: out.Table.TableArn,
+			Tags:        tags,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
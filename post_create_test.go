@@ -0,0 +1,71 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type postCreateMock struct {
+	Mock
+	ttl     *dynamodb.UpdateTimeToLiveInput
+	backups *dynamodb.UpdateContinuousBackupsInput
+	tags    *dynamodb.TagResourceInput
+}
+
+func (m *postCreateMock) UpdateTimeToLiveWithContext(_ aws.Context, input *dynamodb.UpdateTimeToLiveInput, _ ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	m.ttl = input
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func (m *postCreateMock) UpdateContinuousBackupsWithContext(_ aws.Context, input *dynamodb.UpdateContinuousBackupsInput, _ ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	m.backups = input
+	return &dynamodb.UpdateContinuousBackupsOutput{}, nil
+}
+
+func (m *postCreateMock) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{
+		Table: &dynamodb.TableDescription{
+			TableArn: aws.String("arn:aws:dynamodb:us-east-1:123456789012:table/blah"),
+		},
+	}, nil
+}
+
+func (m *postCreateMock) TagResourceWithContext(_ aws.Context, input *dynamodb.TagResourceInput, _ ...request.Option) (*dynamodb.TagResourceOutput, error) {
+	m.tags = input
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
+func TestCreateTableIfNotExists_postCreateOptions(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		tableName   = "blah"
+		hashKeyName = "id"
+		hashKeyType = dynamodb.ScalarAttributeTypeS
+	)
+
+	mock := &postCreateMock{}
+	table := New(mock, tableName)
+
+	err := table.CreateTableIfNotExists(ctx, hashKeyName, hashKeyType,
+		WithTimeToLive("expires_at"),
+		WithPointInTimeRecovery(true),
+		WithTags(map[string]string{"env": "test"}),
+	)
+	if err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	if mock.ttl == nil || aws.StringValue(mock.ttl.TimeToLiveSpecification.AttributeName) != "expires_at" {
+		t.Fatalf("got %#v; want TTL update for expires_at", mock.ttl)
+	}
+	if mock.backups == nil || !aws.BoolValue(mock.backups.PointInTimeRecoverySpecification.PointInTimeRecoveryEnabled) {
+		t.Fatalf("got %#v; want PITR enabled", mock.backups)
+	}
+	if mock.tags == nil || len(mock.tags.Tags) != 1 {
+		t.Fatalf("got %#v; want one tag", mock.tags)
+	}
+}
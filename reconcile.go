@@ -0,0 +1,301 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IndexAction describes what ReconcileTable needs to do to converge a single
+// global secondary index.
+type IndexAction string
+
+const (
+	IndexActionCreate   IndexAction = "CREATE"
+	IndexActionDelete   IndexAction = "DELETE"
+	IndexActionUpdate   IndexAction = "UPDATE"   // provisioned throughput only
+	IndexActionRecreate IndexAction = "RECREATE" // key schema or projection changed
+)
+
+// IndexPlan is one entry in a ReconcilePlan describing the action
+// ReconcileTable will take, or took, for a single global secondary index.
+type IndexPlan struct {
+	IndexName string
+	Action    IndexAction
+}
+
+// ReconcilePlan describes the changes ReconcileTable would make, or made, to
+// converge a table's live schema with a desired set of TableOptions.
+type ReconcilePlan struct {
+	TableName              string
+	BillingModeChanged     bool
+	ThroughputChanged      bool
+	GlobalSecondaryIndexes []IndexPlan
+}
+
+// IsEmpty reports whether the plan has no changes to apply.
+func (p ReconcilePlan) IsEmpty() bool {
+	return !p.BillingModeChanged && !p.ThroughputChanged && len(p.GlobalSecondaryIndexes) == 0
+}
+
+// PlanReconcile diffs the table's live schema against opts and returns the
+// plan ReconcileTable would execute, without making any changes. Callers can
+// use this to dry-run a reconciliation.
+func (t *Table) PlanReconcile(ctx context.Context, opts ...TableOption) (ReconcilePlan, error) {
+	desired := makeTableOptions(opts)
+
+	out, err := t.api.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return ReconcilePlan{}, err
+	}
+
+	return diffTable(t.tableName, desired, out.Table), nil
+}
+
+// ReconcileTable brings the table's live schema in line with opts, issuing
+// the minimal set of UpdateTable calls required to converge. It returns the
+// plan it executed so callers can inspect what changed.
+func (t *Table) ReconcileTable(ctx context.Context, opts ...TableOption) (ReconcilePlan, error) {
+	desired := makeTableOptions(opts)
+
+	out, err := t.api.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	})
+	if err != nil {
+		return ReconcilePlan{}, err
+	}
+
+	plan := diffTable(t.tableName, desired, out.Table)
+
+	if plan.BillingModeChanged || plan.ThroughputChanged {
+		input := &dynamodb.UpdateTableInput{
+			TableName:   aws.String(t.tableName),
+			BillingMode: aws.String(desired.billingMode),
+		}
+		if desired.billingMode != dynamodb.BillingModePayPerRequest {
+			input.ProvisionedThroughput = makeProvisionedThroughput(desired.billingMode, desired.readCapacityUnits, desired.writeCapacityUnits)
+		}
+		if _, err := t.api.UpdateTableWithContext(ctx, input); err != nil {
+			return plan, err
+		}
+		if err := t.waitUntilActive(ctx); err != nil {
+			return plan, err
+		}
+	}
+
+	// DynamoDB only accepts a GSI operation while the table is ACTIVE, and
+	// puts the table into UPDATING for the duration of the operation, so
+	// each plan entry must wait for ACTIVE again before the next one fires.
+	for _, idx := range plan.GlobalSecondaryIndexes {
+		if err := t.applyIndexAction(ctx, desired, idx); err != nil {
+			return plan, err
+		}
+	}
+
+	return plan, nil
+}
+
+// waitUntilActive blocks until the table leaves UPDATING/CREATING and
+// returns to ACTIVE. DynamoDB rejects a second table-level operation (e.g.
+// another GSI create/delete/update) while one is already in flight.
+func (t *Table) waitUntilActive(ctx context.Context) error {
+	return t.api.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(t.tableName),
+	})
+}
+
+func (t *Table) applyIndexAction(ctx context.Context, desired tableOptions, plan IndexPlan) error {
+	switch plan.Action {
+	case IndexActionDelete, IndexActionRecreate:
+		if _, err := t.api.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+			TableName: aws.String(t.tableName),
+			GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+				{Delete: &dynamodb.DeleteGlobalSecondaryIndexAction{IndexName: aws.String(plan.IndexName)}},
+			},
+		}); err != nil {
+			return err
+		}
+		if err := t.waitUntilActive(ctx); err != nil {
+			return err
+		}
+		if plan.Action == IndexActionDelete {
+			return nil
+		}
+		return t.createIndex(ctx, desired, plan.IndexName)
+	case IndexActionCreate:
+		return t.createIndex(ctx, desired, plan.IndexName)
+	case IndexActionUpdate:
+		for _, fn := range desired.globalIndexes {
+			gsi, _ := fn(desired.billingMode)
+			if gsi.indexName != plan.IndexName {
+				continue
+			}
+			if _, err := t.api.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+				TableName: aws.String(t.tableName),
+				GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+					{Update: &dynamodb.UpdateGlobalSecondaryIndexAction{
+						IndexName:             aws.String(gsi.indexName),
+						ProvisionedThroughput: makeProvisionedThroughput(gsi.billingMode, gsi.readCapacityUnits, gsi.writeCapacityUnits),
+					}},
+				},
+			}); err != nil {
+				return err
+			}
+			return t.waitUntilActive(ctx)
+		}
+		return fmt.Errorf("dynamo: index %q not found in desired options", plan.IndexName)
+	}
+
+	return nil
+}
+
+func (t *Table) createIndex(ctx context.Context, desired tableOptions, indexName string) error {
+	for _, fn := range desired.globalIndexes {
+		gsi, attributes := fn(desired.billingMode)
+		if gsi.indexName != indexName {
+			continue
+		}
+
+		if _, err := t.api.UpdateTableWithContext(ctx, &dynamodb.UpdateTableInput{
+			TableName:            aws.String(t.tableName),
+			AttributeDefinitions: merge(nil, attributes...),
+			GlobalSecondaryIndexUpdates: []*dynamodb.GlobalSecondaryIndexUpdate{
+				{Create: &dynamodb.CreateGlobalSecondaryIndexAction{
+					IndexName: aws.String(gsi.indexName),
+					KeySchema: makeKeySchemaElements(gsi.keys),
+					Projection: &dynamodb.Projection{
+						NonKeyAttributes: aws.StringSlice(gsi.nonKeyAttributes),
+						ProjectionType:   aws.String(gsi.projectionType),
+					},
+					ProvisionedThroughput: makeProvisionedThroughput(gsi.billingMode, gsi.readCapacityUnits, gsi.writeCapacityUnits),
+				}},
+			},
+		}); err != nil {
+			return err
+		}
+
+		return t.waitUntilActive(ctx)
+	}
+
+	return fmt.Errorf("dynamo: index %q not found in desired options", indexName)
+}
+
+func diffTable(tableName string, desired tableOptions, live *dynamodb.TableDescription) ReconcilePlan {
+	plan := ReconcilePlan{TableName: tableName}
+
+	liveBillingMode := dynamodb.BillingModeProvisioned
+	if live.BillingModeSummary != nil && live.BillingModeSummary.BillingMode != nil {
+		liveBillingMode = *live.BillingModeSummary.BillingMode
+	}
+	if liveBillingMode != desired.billingMode {
+		plan.BillingModeChanged = true
+	}
+	if desired.billingMode != dynamodb.BillingModePayPerRequest && live.ProvisionedThroughput != nil {
+		if aws.Int64Value(live.ProvisionedThroughput.ReadCapacityUnits) != desired.readCapacityUnits ||
+			aws.Int64Value(live.ProvisionedThroughput.WriteCapacityUnits) != desired.writeCapacityUnits {
+			plan.ThroughputChanged = true
+		}
+	}
+
+	liveIndexes := make(map[string]*dynamodb.GlobalSecondaryIndexDescription, len(live.GlobalSecondaryIndexes))
+	for _, gsi := range live.GlobalSecondaryIndexes {
+		liveIndexes[aws.StringValue(gsi.IndexName)] = gsi
+	}
+
+	desiredIndexes := make(map[string]globalSecondaryIndex, len(desired.globalIndexes))
+	for _, fn := range desired.globalIndexes {
+		gsi, _ := fn(desired.billingMode)
+		desiredIndexes[gsi.indexName] = gsi
+	}
+
+	for name, gsi := range desiredIndexes {
+		liveIndex, ok := liveIndexes[name]
+		if !ok {
+			plan.GlobalSecondaryIndexes = append(plan.GlobalSecondaryIndexes, IndexPlan{IndexName: name, Action: IndexActionCreate})
+			continue
+		}
+		if indexSchemaChanged(gsi, liveIndex) {
+			plan.GlobalSecondaryIndexes = append(plan.GlobalSecondaryIndexes, IndexPlan{IndexName: name, Action: IndexActionRecreate})
+			continue
+		}
+		if indexThroughputChanged(gsi, liveIndex) {
+			plan.GlobalSecondaryIndexes = append(plan.GlobalSecondaryIndexes, IndexPlan{IndexName: name, Action: IndexActionUpdate})
+		}
+	}
+	for name := range liveIndexes {
+		if _, ok := desiredIndexes[name]; !ok {
+			plan.GlobalSecondaryIndexes = append(plan.GlobalSecondaryIndexes, IndexPlan{IndexName: name, Action: IndexActionDelete})
+		}
+	}
+
+	sort.Slice(plan.GlobalSecondaryIndexes, func(i, j int) bool {
+		return plan.GlobalSecondaryIndexes[i].IndexName < plan.GlobalSecondaryIndexes[j].IndexName
+	})
+
+	return plan
+}
+
+func indexSchemaChanged(desired globalSecondaryIndex, live *dynamodb.GlobalSecondaryIndexDescription) bool {
+	if !keySchemaEqual(makeKeySchemaElements(desired.keys), live.KeySchema) {
+		return true
+	}
+	if live.Projection == nil {
+		return true
+	}
+	if aws.StringValue(live.Projection.ProjectionType) != desired.projectionType {
+		return true
+	}
+
+	// AWS does not guarantee a stable order for NonKeyAttributes, so the
+	// projection must be compared as a set; a list comparison reports a
+	// spurious change whenever the API happens to return them reshuffled.
+	return !stringSetEqual(desired.nonKeyAttributes, aws.StringValueSlice(live.Projection.NonKeyAttributes))
+}
+
+func indexThroughputChanged(desired globalSecondaryIndex, live *dynamodb.GlobalSecondaryIndexDescription) bool {
+	if desired.billingMode == dynamodb.BillingModePayPerRequest {
+		return false
+	}
+	if live.ProvisionedThroughput == nil {
+		return true
+	}
+
+	return aws.Int64Value(live.ProvisionedThroughput.ReadCapacityUnits) != desired.readCapacityUnits ||
+		aws.Int64Value(live.ProvisionedThroughput.WriteCapacityUnits) != desired.writeCapacityUnits
+}
+
+func keySchemaEqual(a, b []*dynamodb.KeySchemaElement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if aws.StringValue(a[i].AttributeName) != aws.StringValue(b[i].AttributeName) ||
+			aws.StringValue(a[i].KeyType) != aws.StringValue(b[i].KeyType) {
+			return false
+		}
+	}
+	return true
+}
+
+func stringSetEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[string]struct{}, len(a))
+	for _, s := range a {
+		set[s] = struct{}{}
+	}
+	for _, s := range b {
+		if _, ok := set[s]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
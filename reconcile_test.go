@@ -0,0 +1,281 @@
+package dynamo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func Test_diffTable(t *testing.T) {
+	const tableName = "blah"
+
+	t.Run("no changes", func(t *testing.T) {
+		desired := makeTableOptions([]TableOption{
+			WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+			WithGlobalSecondaryIndex("gsi", dynamodb.ProjectionTypeInclude,
+				WithAttr("a", dynamodb.ScalarAttributeTypeS),
+				WithAttr("b", dynamodb.ScalarAttributeTypeS),
+			),
+		})
+		live := &dynamodb.TableDescription{
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+				{
+					IndexName: aws.String("gsi"),
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String(dynamodb.ProjectionTypeInclude),
+						// reshuffled relative to desired: this must not be
+						// reported as a change.
+						NonKeyAttributes: aws.StringSlice([]string{"b", "a"}),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+						WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+					},
+				},
+			},
+		}
+
+		plan := diffTable(tableName, desired, live)
+		if !plan.IsEmpty() {
+			t.Fatalf("got %#v; want empty plan", plan)
+		}
+	})
+
+	t.Run("create missing index", func(t *testing.T) {
+		desired := makeTableOptions([]TableOption{
+			WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+			WithGlobalSecondaryIndex("gsi", dynamodb.ProjectionTypeAll),
+		})
+		live := &dynamodb.TableDescription{
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+		}
+
+		plan := diffTable(tableName, desired, live)
+		want := []IndexPlan{{IndexName: "gsi", Action: IndexActionCreate}}
+		if len(plan.GlobalSecondaryIndexes) != 1 || plan.GlobalSecondaryIndexes[0] != want[0] {
+			t.Fatalf("got %#v; want %#v", plan.GlobalSecondaryIndexes, want)
+		}
+	})
+
+	t.Run("delete removed index", func(t *testing.T) {
+		desired := makeTableOptions([]TableOption{
+			WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+		})
+		live := &dynamodb.TableDescription{
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+				{
+					IndexName: aws.String("gsi"),
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String(dynamodb.ProjectionTypeAll),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+						WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+					},
+				},
+			},
+		}
+
+		plan := diffTable(tableName, desired, live)
+		want := []IndexPlan{{IndexName: "gsi", Action: IndexActionDelete}}
+		if len(plan.GlobalSecondaryIndexes) != 1 || plan.GlobalSecondaryIndexes[0] != want[0] {
+			t.Fatalf("got %#v; want %#v", plan.GlobalSecondaryIndexes, want)
+		}
+	})
+
+	t.Run("capacity only change is an update, not a recreate", func(t *testing.T) {
+		desired := makeTableOptions([]TableOption{
+			WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+			WithGlobalSecondaryIndex("gsi", dynamodb.ProjectionTypeAll, WithReadCapacity(10)),
+		})
+		live := &dynamodb.TableDescription{
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+				{
+					IndexName: aws.String("gsi"),
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String(dynamodb.ProjectionTypeAll),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+						WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+					},
+				},
+			},
+		}
+
+		plan := diffTable(tableName, desired, live)
+		want := []IndexPlan{{IndexName: "gsi", Action: IndexActionUpdate}}
+		if len(plan.GlobalSecondaryIndexes) != 1 || plan.GlobalSecondaryIndexes[0] != want[0] {
+			t.Fatalf("got %#v; want %#v", plan.GlobalSecondaryIndexes, want)
+		}
+	})
+
+	t.Run("projection change requires a recreate", func(t *testing.T) {
+		desired := makeTableOptions([]TableOption{
+			WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+			WithGlobalSecondaryIndex("gsi", dynamodb.ProjectionTypeAll),
+		})
+		live := &dynamodb.TableDescription{
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+				WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+				{
+					IndexName: aws.String("gsi"),
+					Projection: &dynamodb.Projection{
+						ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly),
+					},
+					ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+						WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+					},
+				},
+			},
+		}
+
+		plan := diffTable(tableName, desired, live)
+		want := []IndexPlan{{IndexName: "gsi", Action: IndexActionRecreate}}
+		if len(plan.GlobalSecondaryIndexes) != 1 || plan.GlobalSecondaryIndexes[0] != want[0] {
+			t.Fatalf("got %#v; want %#v", plan.GlobalSecondaryIndexes, want)
+		}
+	})
+}
+
+// reconcileMock records the order UpdateTable/WaitUntilTableExists calls are
+// made in, so tests can assert ReconcileTable waits for the table to return
+// to ACTIVE between GSI operations rather than firing them back-to-back.
+type reconcileMock struct {
+	Mock
+
+	describeOutput *dynamodb.DescribeTableOutput
+	calls          []string
+}
+
+func (m *reconcileMock) DescribeTableWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	m.calls = append(m.calls, "describe")
+	return m.describeOutput, nil
+}
+
+func (m *reconcileMock) UpdateTableWithContext(_ aws.Context, input *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	switch {
+	case len(input.GlobalSecondaryIndexUpdates) > 0:
+		gsiu := input.GlobalSecondaryIndexUpdates[0]
+		switch {
+		case gsiu.Delete != nil:
+			m.calls = append(m.calls, "delete:"+aws.StringValue(gsiu.Delete.IndexName))
+		case gsiu.Create != nil:
+			m.calls = append(m.calls, "create:"+aws.StringValue(gsiu.Create.IndexName))
+		case gsiu.Update != nil:
+			m.calls = append(m.calls, "update:"+aws.StringValue(gsiu.Update.IndexName))
+		}
+	default:
+		m.calls = append(m.calls, "billing")
+	}
+	return &dynamodb.UpdateTableOutput{}, nil
+}
+
+func (m *reconcileMock) WaitUntilTableExistsWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.WaiterOption) error {
+	m.calls = append(m.calls, "wait")
+	return nil
+}
+
+func TestTable_ReconcileTable_recreate(t *testing.T) {
+	mock := &reconcileMock{
+		describeOutput: &dynamodb.DescribeTableOutput{
+			Table: &dynamodb.TableDescription{
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+					ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+					WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+				},
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+					{
+						IndexName: aws.String("gsi"),
+						Projection: &dynamodb.Projection{
+							ProjectionType: aws.String(dynamodb.ProjectionTypeKeysOnly),
+						},
+						ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+							ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+							WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+						},
+					},
+				},
+			},
+		},
+	}
+	opts := []TableOption{
+		WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+		WithGlobalSecondaryIndex("gsi", dynamodb.ProjectionTypeAll),
+	}
+	table := New(mock, "blah", opts...)
+
+	if _, err := table.ReconcileTable(context.Background(), opts...); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	want := []string{"describe", "delete:gsi", "wait", "create:gsi", "wait"}
+	if !reflect.DeepEqual(mock.calls, want) {
+		t.Fatalf("got %v; want %v", mock.calls, want)
+	}
+}
+
+func TestTable_ReconcileTable_multiGSI(t *testing.T) {
+	mock := &reconcileMock{
+		describeOutput: &dynamodb.DescribeTableOutput{
+			Table: &dynamodb.TableDescription{
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+					ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+					WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+				},
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndexDescription{
+					{
+						IndexName: aws.String("old"),
+						Projection: &dynamodb.Projection{
+							ProjectionType: aws.String(dynamodb.ProjectionTypeAll),
+						},
+						ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
+							ReadCapacityUnits:  aws.Int64(DefaultReadCapacity),
+							WriteCapacityUnits: aws.Int64(DefaultWriteCapacity),
+						},
+					},
+				},
+			},
+		},
+	}
+	opts := []TableOption{
+		WithHashKey("id", dynamodb.ScalarAttributeTypeS),
+		WithGlobalSecondaryIndex("new", dynamodb.ProjectionTypeAll),
+	}
+	table := New(mock, "blah", opts...)
+
+	if _, err := table.ReconcileTable(context.Background(), opts...); err != nil {
+		t.Fatalf("got %v; want nil", err)
+	}
+
+	// diffTable sorts plan entries by IndexName ascending, so "new" (create)
+	// is issued before "old" (delete); each must wait for ACTIVE before the
+	// next one fires.
+	want := []string{"describe", "create:new", "wait", "delete:old", "wait"}
+	if !reflect.DeepEqual(mock.calls, want) {
+		t.Fatalf("got %v; want %v", mock.calls, want)
+	}
+}
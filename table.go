@@ -11,7 +11,19 @@ import (
 
 type Table struct {
 	api       dynamodbiface.DynamoDBAPI
+	itemAPI   DAXAPI
 	tableName string
+	schema    tableOptions
+}
+
+// itemClient returns the client item-level operations should be issued
+// against: DAX when the Table was built with NewWithDAX, otherwise the
+// regular DynamoDB client.
+func (t *Table) itemClient() DAXAPI {
+	if t.itemAPI != nil {
+		return t.itemAPI
+	}
+	return t.api
 }
 
 func (t *Table) DeleteTableIfExists(ctx context.Context) error {
@@ -29,9 +41,17 @@ func (t *Table) DeleteTableIfExists(ctx context.Context) error {
 	return nil
 }
 
-func New(api dynamodbiface.DynamoDBAPI, tableName string) *Table {
+// New builds a Table backed by the v1 aws-sdk-go client. New callers should
+// prefer NewV2, which targets aws-sdk-go-v2; this constructor is kept for a
+// deprecation window while existing callers migrate.
+//
+// opts configure the table's key schema (WithHashKey, WithRangeKey,
+// WithGlobalSecondaryIndex) so the item API (Get, Put, Query, ...) can build
+// keys and conditions without callers repeating that schema on every call.
+func New(api dynamodbiface.DynamoDBAPI, tableName string, opts ...TableOption) *Table {
 	return &Table{
 		api:       api,
 		tableName: tableName,
+		schema:    makeTableOptions(opts),
 	}
 }
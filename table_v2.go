@@ -0,0 +1,41 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableV2 is the aws-sdk-go-v2 counterpart of Table. It exists so callers on
+// modern SDKs (and DAX v2 wrappers) can plug a client directly into this
+// package without depending on aws-sdk-go.
+type TableV2 struct {
+	api       DynamoDBAPI
+	tableName string
+}
+
+func (t *TableV2) DeleteTableIfExists(ctx context.Context) error {
+	input := dynamodb.DeleteTableInput{
+		TableName: &t.tableName,
+	}
+	if _, err := t.api.DeleteTable(ctx, &input); err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// NewV2 builds a TableV2 backed by the aws-sdk-go-v2 client api.
+func NewV2(api DynamoDBAPI, tableName string) *TableV2 {
+	return &TableV2{
+		api:       api,
+		tableName: tableName,
+	}
+}